@@ -3,6 +3,7 @@ package cosmos
 import (
 	"context"
 	"fmt"
+	"sync"
 
 	// nolint:staticcheck
 
@@ -15,15 +16,104 @@ import (
 const (
 	icsVer330 = "v3.3.0"
 	icsVer400 = "v4.0.0"
+
+	// defaultICSProviderSetupVersion is used by FinishICSProviderSetup when no WithICSVersion
+	// option is given, so existing callers continue to get today's behavior unchanged.
+	defaultICSProviderSetupVersion = icsVer400
 )
 
-// FinishICSProviderSetup sets up the base of an ICS connection with respect to the relayer, provider actions, and flushing of packets.
-// 1. Stop the relayer, then start it back up. This completes the ICS20-1 transfer channel setup.
-//   - You must set look-back block history >100 blocks in [interchaintest.NewBuiltinRelayerFactory].
+// ICSProviderSetupStrategy carries out the version-specific sequence of steps needed to finish
+// setting up an ICS connection: completing the relayer's channel, taking whatever provider-side
+// action that version requires (e.g. delegating to trigger a CometBFT power change, or opting a
+// validator in under permissionless ICS), and flushing the resulting packets to the consumer
+// chain.
 //
-// 2. Get the first provider validator, and delegate 1,000,000denom to it. This triggers a CometBFT power increase of 1.
-// 3. Flush the pending ICS packets to the consumer chain.
-func (c *CosmosChain) FinishICSProviderSetup(ctx context.Context, r ibc.Relayer, eRep *testreporter.RelayerExecReporter, ibcPath string) error {
+// Register a strategy for a given provider module version with RegisterICSProviderSetupStrategy;
+// FinishICSProviderSetup looks strategies up by version rather than hardcoding a single flow, so
+// downstream projects can plug in support for new ICS versions without forking this package.
+type ICSProviderSetupStrategy interface {
+	FinishSetup(ctx context.Context, c *CosmosChain, r ibc.Relayer, eRep *testreporter.RelayerExecReporter, ibcPath string) error
+}
+
+// icsProviderSetupStrategiesMu guards icsProviderSetupStrategies, since RegisterICSProviderSetupStrategy
+// is a public extension point downstream projects may call concurrently with FinishICSProviderSetup
+// reading it from a parallel test.
+var icsProviderSetupStrategiesMu sync.RWMutex
+
+// icsProviderSetupStrategies maps a provider module's ICS version string to the
+// ICSProviderSetupStrategy registered for it. Access only through RegisterICSProviderSetupStrategy
+// and icsProviderSetupStrategyFor, which hold icsProviderSetupStrategiesMu.
+var icsProviderSetupStrategies = map[string]ICSProviderSetupStrategy{}
+
+// RegisterICSProviderSetupStrategy registers strategy as the ICSProviderSetupStrategy used by
+// FinishICSProviderSetup for provider module version icsVersion, overwriting any strategy
+// previously registered for that version. Call this from an init func, e.g. to contribute support
+// for a new ICS release or a custom consumer-chain flow without forking this package.
+func RegisterICSProviderSetupStrategy(icsVersion string, strategy ICSProviderSetupStrategy) {
+	icsProviderSetupStrategiesMu.Lock()
+	defer icsProviderSetupStrategiesMu.Unlock()
+	icsProviderSetupStrategies[icsVersion] = strategy
+}
+
+// icsProviderSetupStrategyFor returns the strategy registered for icsVersion, if any.
+func icsProviderSetupStrategyFor(icsVersion string) (ICSProviderSetupStrategy, bool) {
+	icsProviderSetupStrategiesMu.RLock()
+	defer icsProviderSetupStrategiesMu.RUnlock()
+	strategy, ok := icsProviderSetupStrategies[icsVersion]
+	return strategy, ok
+}
+
+func init() {
+	// v3.3.0 and v4.0.0 share the same delegate-then-flush flow today. They're registered
+	// separately, rather than defaulted, so a future version can override just one of them
+	// without affecting the other.
+	RegisterICSProviderSetupStrategy(icsVer330, delegateAndFlushStrategy{})
+	RegisterICSProviderSetupStrategy(icsVer400, delegateAndFlushStrategy{})
+}
+
+// ICSProviderSetupOption customizes FinishICSProviderSetup.
+type ICSProviderSetupOption func(*icsProviderSetupConfig)
+
+type icsProviderSetupConfig struct {
+	version string
+}
+
+// WithICSVersion selects the ICSProviderSetupStrategy registered for icsVersion instead of
+// defaultICSProviderSetupVersion. Use this for tests that target a specific provider module
+// version, e.g. to exercise the permissionless ICS opt-in flow once a strategy is registered for
+// it.
+func WithICSVersion(icsVersion string) ICSProviderSetupOption {
+	return func(cfg *icsProviderSetupConfig) {
+		cfg.version = icsVersion
+	}
+}
+
+// FinishICSProviderSetup sets up the base of an ICS connection with respect to the relayer, provider actions, and flushing of packets.
+// The exact sequence of steps is determined by the ICSProviderSetupStrategy registered for the
+// requested ICS version (defaultICSProviderSetupVersion unless overridden with WithICSVersion).
+func (c *CosmosChain) FinishICSProviderSetup(ctx context.Context, r ibc.Relayer, eRep *testreporter.RelayerExecReporter, ibcPath string, opts ...ICSProviderSetupOption) error {
+	cfg := icsProviderSetupConfig{version: defaultICSProviderSetupVersion}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	strategy, ok := icsProviderSetupStrategyFor(cfg.version)
+	if !ok {
+		return fmt.Errorf("no ICSProviderSetupStrategy registered for ICS version %q", cfg.version)
+	}
+
+	return strategy.FinishSetup(ctx, c, r, eRep, ibcPath)
+}
+
+// delegateAndFlushStrategy implements the ICS provider setup flow used prior to the introduction
+// of ICSProviderSetupStrategy:
+//  1. Stop the relayer, then start it back up. This completes the ICS20-1 transfer channel setup.
+//     - You must set look-back block history >100 blocks in [interchaintest.NewBuiltinRelayerFactory].
+//  2. Get the first provider validator, and delegate 1,000,000denom to it. This triggers a CometBFT power increase of 1.
+//  3. Flush the pending ICS packets to the consumer chain.
+type delegateAndFlushStrategy struct{}
+
+func (delegateAndFlushStrategy) FinishSetup(ctx context.Context, c *CosmosChain, r ibc.Relayer, eRep *testreporter.RelayerExecReporter, ibcPath string) error {
 	// Restart the relayer to finish IBC transfer connection w/ ics20-1 link
 	if err := r.StopRelayer(ctx, eRep); err != nil {
 		return fmt.Errorf("failed to stop relayer: %w", err)
@@ -0,0 +1,99 @@
+package cosmos
+
+import (
+	"context"
+	"testing"
+
+	"github.com/strangelove-ventures/interchaintest/v8/ibc"
+	"github.com/strangelove-ventures/interchaintest/v8/testreporter"
+)
+
+// recordingICSStrategy is a test double that records whether it was invoked, so tests can assert
+// FinishICSProviderSetup dispatched to the expected ICSProviderSetupStrategy without needing a
+// live relayer or chain.
+type recordingICSStrategy struct {
+	called bool
+}
+
+func (s *recordingICSStrategy) FinishSetup(ctx context.Context, c *CosmosChain, r ibc.Relayer, eRep *testreporter.RelayerExecReporter, ibcPath string) error {
+	s.called = true
+	return nil
+}
+
+func TestFinishICSProviderSetupStrategySelection(t *testing.T) {
+	const customVersion = "v9.9.9-test"
+
+	cases := []struct {
+		name        string
+		version     string // "" means rely on defaultICSProviderSetupVersion
+		registerFor string // version to register the recordingICSStrategy under
+		wantErr     bool
+	}{
+		{
+			name:        "uses default version when no option given",
+			registerFor: defaultICSProviderSetupVersion,
+		},
+		{
+			name:        "explicit v3.3.0",
+			version:     icsVer330,
+			registerFor: icsVer330,
+		},
+		{
+			name:        "explicit v4.0.0",
+			version:     icsVer400,
+			registerFor: icsVer400,
+		},
+		{
+			name:        "custom registered version",
+			version:     customVersion,
+			registerFor: customVersion,
+		},
+		{
+			name:    "unregistered version errors",
+			version: "v0.0.0-unregistered",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			strategy := &recordingICSStrategy{}
+			if tc.registerFor != "" {
+				prev, hadPrev := icsProviderSetupStrategyFor(tc.registerFor)
+				RegisterICSProviderSetupStrategy(tc.registerFor, strategy)
+				defer func() {
+					icsProviderSetupStrategiesMu.Lock()
+					defer icsProviderSetupStrategiesMu.Unlock()
+					if hadPrev {
+						icsProviderSetupStrategies[tc.registerFor] = prev
+					} else {
+						delete(icsProviderSetupStrategies, tc.registerFor)
+					}
+				}()
+			}
+
+			var opts []ICSProviderSetupOption
+			if tc.version != "" {
+				opts = append(opts, WithICSVersion(tc.version))
+			}
+
+			c := &CosmosChain{}
+			err := c.FinishICSProviderSetup(context.Background(), nil, nil, "ibc-path", opts...)
+
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error for an unregistered ICS version, got nil")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !strategy.called {
+				t.Fatalf("expected the registered strategy to be invoked")
+			}
+		})
+	}
+}
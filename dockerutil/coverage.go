@@ -0,0 +1,241 @@
+package dockerutil
+
+import (
+	"archive/tar"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+)
+
+// coveragePkgFilter returns the set of image/container name substrings that coverage export
+// should be restricted to, from the ICTEST_COVERAGE_PKG_FILTER environment variable (comma
+// separated, e.g. "gaiad,osmosisd" to only export coverage from chain node containers and skip
+// the relayer). An empty filter matches everything.
+func coveragePkgFilter() []string {
+	raw := os.Getenv("ICTEST_COVERAGE_PKG_FILTER")
+	if raw == "" {
+		return nil
+	}
+
+	var pkgs []string
+	for _, p := range strings.Split(raw, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			pkgs = append(pkgs, p)
+		}
+	}
+	return pkgs
+}
+
+// matchesPkgFilter reports whether name (a container name or image reference) should have its
+// coverage data exported, given the ICTEST_COVERAGE_PKG_FILTER filter.
+func matchesPkgFilter(pkgFilter []string, name string) bool {
+	if len(pkgFilter) == 0 {
+		return true
+	}
+	for _, pkg := range pkgFilter {
+		if strings.Contains(name, pkg) {
+			return true
+		}
+	}
+	return false
+}
+
+// outCoverageDataDir returns the directory coverage data should be written to for the given test,
+// honoring the ICTEST_GOCOVERDIR override.
+func outCoverageDataDir(t DockerSetupTestingT) string {
+	if dir := os.Getenv("ICTEST_GOCOVERDIR"); dir != "" {
+		return dir
+	}
+	return "coverage/" + t.Name()
+}
+
+// DockerExportCoverageData guarantees the cleanup, but also exports coverage data from the containers beforehand.
+func DockerExportCoverageDataFn(t DockerSetupTestingT, cli *client.Client) func() {
+	return func() {
+		defer func() {
+			if e := recover(); e != nil {
+				t.Logf("Failed to export coverage data: %v", e)
+				return
+			}
+		}()
+
+		outDir := outCoverageDataDir(t)
+		pkgFilter := coveragePkgFilter()
+
+		ctx := context.TODO()
+		cli.NegotiateAPIVersion(ctx)
+		cs, err := cli.ContainerList(ctx, types.ContainerListOptions{
+			All: true,
+			Filters: filters.NewArgs(
+				filters.Arg("label", CleanupLabel+"="+t.Name()),
+			),
+		})
+		if err != nil {
+			t.Logf("Failed to list containers during docker export coverage data: %v", err)
+			return
+		}
+
+		for _, c := range cs {
+			if !matchesPkgFilter(pkgFilter, containerDisplayName(c)) {
+				continue
+			}
+			exportContainerCoverage(ctx, t, cli, c.ID, containerDisplayName(c), outDir)
+		}
+
+		mergeCoverageData(t, outDir)
+	}
+}
+
+// containerDisplayName returns c's first name, falling back to its truncated ID.
+func containerDisplayName(c types.Container) string {
+	if len(c.Names) > 0 {
+		return c.Names[0]
+	}
+	return c.ID[:12]
+}
+
+// exportContainerCoverage exports GOCOVERDIR data from a single container into
+// outCoverageDataDir, if the container was run with GOCOVERDIR set. It's split out of
+// DockerExportCoverageDataFn (and shared with the eager Job export path) so every reader/file it
+// opens is closed at the end of each container's export rather than deferred to the end of a
+// whole loop, which previously kept file descriptors open until every container in the test had
+// been processed.
+func exportContainerCoverage(ctx context.Context, t DockerSetupTestingT, cli *client.Client, containerID, containerName, outDir string) {
+	// Get GOCOVERDIR environment variable from container
+	containerInspect, err := cli.ContainerInspect(ctx, containerID)
+	if err != nil {
+		t.Logf("Failed to inspect container %s: %v", containerID, err)
+		return
+	}
+
+	var coverageDataDir string
+	for _, env := range containerInspect.Config.Env {
+		if strings.HasPrefix(env, "GOCOVERDIR=") {
+			coverageDataDir = strings.TrimPrefix(env, "GOCOVERDIR=")
+			break
+		}
+	}
+
+	// coverage data not enabled for export
+	if coverageDataDir == "" {
+		return
+	}
+
+	t.Logf("Exporting coverage data %s from container %s", coverageDataDir, containerName)
+
+	// Copy coverage data from container to local filesystem
+	reader, _, err := cli.CopyFromContainer(ctx, containerID, coverageDataDir)
+	if err != nil {
+		t.Logf("Failed to copy coverage data from container %s: %v", containerID, err)
+		return
+	}
+	defer reader.Close()
+
+	// Create full path for coverage data
+	containerCoverageDataDir := filepath.Join(outDir, containerName)
+	if err := os.MkdirAll(containerCoverageDataDir, 0755); err != nil {
+		t.Logf("Failed to create coverage data directory for container %s: %v", containerID, err)
+		return
+	}
+
+	// Extract the tar archive containing coverage data
+	tr := tar.NewReader(reader)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Logf("Failed to read tar header from container %s: %v", containerID, err)
+			break
+		}
+
+		// Skip directories
+		if header.Typeflag == tar.TypeDir {
+			continue
+		}
+
+		if err := writeCoverageFile(tr, containerCoverageDataDir, header.Name); err != nil {
+			t.Logf("Failed to write coverage data file for container %s: %v", containerID, err)
+		}
+	}
+}
+
+// writeCoverageFile writes a single file from a coverage tar archive to outDir, closing the
+// destination file before returning rather than deferring to the caller's lifetime.
+func writeCoverageFile(tr *tar.Reader, outDir, name string) error {
+	outPath := filepath.Join(outDir, filepath.Base(name))
+	outFile, err := os.Create(outPath)
+	if err != nil {
+		return fmt.Errorf("failed to create coverage data file %s: %w", outPath, err)
+	}
+	defer outFile.Close()
+
+	if _, err := io.Copy(outFile, tr); err != nil {
+		return fmt.Errorf("failed to write coverage data file %s: %w", outPath, err)
+	}
+	return nil
+}
+
+// ExportJobCoverage eagerly exports GOCOVERDIR coverage data from a short-lived "job" container
+// (e.g. a one-shot `docker run --rm` invocation used by the tx/keys subcommands) before it's
+// removed. Unlike DockerExportCoverageDataFn, which only sees containers still present at test
+// cleanup, this must be called by the Job/exec caller itself, since the container may already be
+// gone by the time cleanup runs.
+//
+// pkgName is matched against ICTEST_COVERAGE_PKG_FILTER and should be the job's image reference
+// (e.g. "gaiad:v18.0.0"), not its container ID or name - a Job's container name is just a random
+// hex ID, which the filter could never usefully match against. containerName is used only to
+// name the on-disk coverage output directory and in log messages.
+func ExportJobCoverage(ctx context.Context, t DockerSetupTestingT, cli *client.Client, containerID, containerName, pkgName string) {
+	if !matchesPkgFilter(coveragePkgFilter(), pkgName) {
+		return
+	}
+	exportContainerCoverage(ctx, t, cli, containerID, containerName, outCoverageDataDir(t))
+}
+
+// mergeCoverageData merges every per-container covdata directory under outDir into
+// outDir/merged using `go tool covdata merge`, so the result is directly usable by
+// `go tool covdata textfmt` without the caller needing to know about the per-container
+// subdirectory layout.
+func mergeCoverageData(t DockerSetupTestingT, outDir string) {
+	entries, err := os.ReadDir(outDir)
+	if err != nil {
+		// Nothing was exported (e.g. coverage wasn't enabled for this run); nothing to merge.
+		return
+	}
+
+	const mergedDirName = "merged"
+
+	var inputDirs []string
+	for _, e := range entries {
+		// Skip the output of an earlier merge, so its counters aren't merged in a second time.
+		if e.IsDir() && e.Name() != mergedDirName {
+			inputDirs = append(inputDirs, filepath.Join(outDir, e.Name()))
+		}
+	}
+	if len(inputDirs) == 0 {
+		return
+	}
+
+	mergedDir := filepath.Join(outDir, mergedDirName)
+	if err := os.MkdirAll(mergedDir, 0755); err != nil {
+		t.Logf("Failed to create merged coverage data directory %s: %v", mergedDir, err)
+		return
+	}
+
+	args := append([]string{"tool", "covdata", "merge", "-o=" + mergedDir, "-i=" + strings.Join(inputDirs, ",")})
+	cmd := exec.Command("go", args...)
+	cmd.Dir = outDir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Logf("Failed to merge coverage data in %s: %v\n%s", outDir, err, out)
+	}
+}
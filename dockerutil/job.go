@@ -0,0 +1,111 @@
+package dockerutil
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// ContainerOptions describes a short-lived container to run to completion via Job.Run, e.g. a
+// `docker run --rm` invocation backing a tx or keys subcommand.
+type ContainerOptions struct {
+	// Image is the image reference to run.
+	Image string
+	// Entrypoint and Cmd are passed through to the container config unmodified.
+	Entrypoint []string
+	Cmd        []string
+	Env        []string
+	// Binds are host:container bind mounts, in Docker's "host:container[:ro]" syntax.
+	Binds []string
+}
+
+// Job runs a single container to completion and removes it, unlike DockerSetup/DockerCleanup
+// which manage containers for the lifetime of a whole test. Because these containers are removed
+// as soon as they exit, DockerExportCoverageDataFn (which only looks at containers still present
+// at test cleanup) never sees them; Job exports their coverage data itself before removal.
+type Job struct {
+	log  DockerSetupTestingT
+	cli  *client.Client
+	opts ContainerOptions
+}
+
+// NewJob returns a Job that will run opts to completion when Run is called.
+func NewJob(t DockerSetupTestingT, cli *client.Client, opts ContainerOptions) *Job {
+	return &Job{log: t, cli: cli, opts: opts}
+}
+
+// Run creates, starts, and waits for the job's container to exit, eagerly exporting any
+// GOCOVERDIR coverage data before the container is removed, then returns the container's stdout
+// and stderr.
+func (j *Job) Run(ctx context.Context) (stdout, stderr string, err error) {
+	created, err := j.cli.ContainerCreate(ctx,
+		&container.Config{
+			Image:      j.opts.Image,
+			Entrypoint: j.opts.Entrypoint,
+			Cmd:        j.opts.Cmd,
+			Env:        j.opts.Env,
+			// Labeled like every other container this package creates, so DockerCleanup, the
+			// reaper, and the eager sweep in the next run of the same test can all find and remove
+			// this container if the test process dies before the defer below runs.
+			Labels: map[string]string{CleanupLabel: j.log.Name()},
+		},
+		&container.HostConfig{
+			Binds:      j.opts.Binds,
+			AutoRemove: false, // Removed explicitly below, after coverage export.
+		},
+		nil, nil, "",
+	)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create job container for image %s: %w", j.opts.Image, err)
+	}
+	containerName := created.ID[:12]
+
+	defer j.removeWithCoverageExport(containerName, created.ID)
+
+	if err := j.cli.ContainerStart(ctx, created.ID, types.ContainerStartOptions{}); err != nil {
+		return "", "", fmt.Errorf("failed to start job container %s: %w", containerName, err)
+	}
+
+	waitCh, errCh := j.cli.ContainerWait(ctx, created.ID, container.WaitConditionNotRunning)
+	select {
+	case err := <-errCh:
+		return "", "", fmt.Errorf("failed to wait for job container %s: %w", containerName, err)
+	case res := <-waitCh:
+		if res.Error != nil {
+			err = fmt.Errorf("job container %s exited with error: %s", containerName, res.Error.Message)
+		} else if res.StatusCode != 0 {
+			err = fmt.Errorf("job container %s exited with status code %d", containerName, res.StatusCode)
+		}
+	}
+
+	logsRC, logsErr := j.cli.ContainerLogs(ctx, created.ID, types.ContainerLogsOptions{ShowStdout: true, ShowStderr: true})
+	if logsErr != nil {
+		return "", "", fmt.Errorf("failed to read logs for job container %s: %w", containerName, logsErr)
+	}
+	defer logsRC.Close()
+
+	var stdoutBuf, stderrBuf bytes.Buffer
+	if _, copyErr := stdcopy.StdCopy(&stdoutBuf, &stderrBuf, logsRC); copyErr != nil {
+		return "", "", fmt.Errorf("failed to demultiplex logs for job container %s: %w", containerName, copyErr)
+	}
+
+	return stdoutBuf.String(), stderrBuf.String(), err
+}
+
+// removeWithCoverageExport copies out GOCOVERDIR coverage data (if the job's env enabled it and
+// the job's image matches ICTEST_COVERAGE_PKG_FILTER) before force-removing the container.
+// Without this, a `docker run --rm`-style job's coverage data would be lost the moment the
+// container exits, since DockerExportCoverageDataFn only sees containers still present at test
+// cleanup.
+func (j *Job) removeWithCoverageExport(containerName, containerID string) {
+	ExportJobCoverage(context.TODO(), j.log, j.cli, containerID, containerName, j.opts.Image)
+
+	if err := j.cli.ContainerRemove(context.TODO(), containerID, types.ContainerRemoveOptions{Force: true}); err != nil {
+		j.log.Logf("Failed to remove job container %s: %v", containerName, err)
+	}
+}
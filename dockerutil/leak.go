@@ -0,0 +1,164 @@
+package dockerutil
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// leakCheckTestingT is the subset of testing.T required by NoLeak. It is kept separate from
+// DockerSetupTestingT because NoLeak is useful on its own, independent of Docker setup.
+type leakCheckTestingT interface {
+	Helper()
+	Cleanup(func())
+	Errorf(format string, args ...any)
+}
+
+// ignoredLeakFrames are substrings of goroutine stack traces that are known-benign and should
+// never fail a test: stdlib/runtime bookkeeping, the testing package itself, and keepalive loops
+// owned by libraries we don't control (the docker client's connection pool, net/http2's
+// readLoop/writeLoop). Anything not matching one of these is considered a genuine leak.
+var ignoredLeakFrames = []string{
+	"testing.(*T).Run",
+	"testing.tRunner",
+	"created by testing.",
+	"runtime.goexit",
+	"internal/poll.runtime_pollWait",
+	"net/http.(*http2ClientConn)",
+	"net/http2.(*ClientConn)",
+	"readLoop",
+	"writeLoop",
+	"github.com/docker/docker/client",
+	"net.(*netFD)",
+}
+
+// NoLeak snapshots the goroutines running when it's called, then at test cleanup diffs the
+// current goroutines against that snapshot and fails the test if any new, non-benign goroutine is
+// still running. Chain/relayer/docker client setup (CosmosChain in particular, with its streaming
+// log tailers, ContainerWait channels, and docker client connections) has historically leaked
+// goroutines across long test suites; NoLeak catches that at the point it happens instead of
+// letting it degrade an entire CI run.
+//
+// Call it near the top of a test, after any one-time package-level setup:
+//
+//	func TestFoo(t *testing.T) {
+//		dockerutil.NoLeak(t)
+//		...
+//	}
+func NoLeak(t leakCheckTestingT) {
+	t.Helper()
+
+	before := snapshotGoroutines()
+
+	t.Cleanup(func() {
+		t.Helper()
+
+		// Leaked goroutines from the just-finished test may not have unwound yet; give them a
+		// moment before declaring them leaked.
+		var after map[string]string
+		deadline := time.Now().Add(2 * time.Second)
+		for {
+			after = diffGoroutines(before, snapshotGoroutines())
+			if len(after) == 0 || time.Now().After(deadline) {
+				break
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+
+		if len(after) == 0 {
+			return
+		}
+
+		var buf bytes.Buffer
+		fmt.Fprintf(&buf, "detected %d leaked goroutine(s):\n", len(after))
+		for root, stack := range after {
+			fmt.Fprintf(&buf, "--- %s ---\n%s\n", root, stack)
+		}
+		t.Errorf("%s", buf.String())
+	})
+}
+
+// CheckGoroutineLeaks wires NoLeak into DockerSetup behind the ICTEST_CHECK_LEAKS environment
+// variable, so existing test suites can opt in without calling NoLeak at every call site. The
+// check runs only if t also has an Errorf method (as *testing.T does); otherwise it is skipped
+// and a message is logged.
+func CheckGoroutineLeaks(t DockerSetupTestingT) {
+	if os.Getenv("ICTEST_CHECK_LEAKS") == "" {
+		return
+	}
+	lt, ok := t.(leakCheckTestingT)
+	if !ok {
+		t.Logf("ICTEST_CHECK_LEAKS is set, but %T has no Errorf method; skipping goroutine leak check", t)
+		return
+	}
+	NoLeak(lt)
+}
+
+// snapshotGoroutines returns the currently running goroutines' full stack traces, keyed by the
+// "goroutine N" ID at the start of each trace. The rest of the header (e.g. "[chan receive,
+// 2 minutes]") changes while a goroutine waits, so it is not part of the key.
+func snapshotGoroutines() map[string]string {
+	buf := make([]byte, 1<<20)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+
+	stacks := map[string]string{}
+	for _, stack := range strings.Split(string(buf), "\n\n") {
+		stack = strings.TrimSpace(stack)
+		if stack == "" {
+			continue
+		}
+		id := stack
+		if i := strings.Index(stack, " ["); i > 0 {
+			id = stack[:i]
+		}
+		stacks[id] = stack
+	}
+	return stacks
+}
+
+// diffGoroutines returns the stacks of goroutines present in after but not before, keyed by their
+// rootFrame, excluding any stack that matches ignoredLeakFrames. Because rootFrame includes the
+// goroutine's ID, each leaked goroutine has its own entry; leaks are not merged by shared frame.
+func diffGoroutines(before, after map[string]string) map[string]string {
+	leaked := map[string]string{}
+	for id, stack := range after {
+		if _, ok := before[id]; ok {
+			continue
+		}
+		if isIgnoredStack(stack) {
+			continue
+		}
+		leaked[rootFrame(stack)] = stack
+	}
+	return leaked
+}
+
+func isIgnoredStack(stack string) bool {
+	for _, frame := range ignoredLeakFrames {
+		if strings.Contains(stack, frame) {
+			return true
+		}
+	}
+	return false
+}
+
+// rootFrame returns the first two lines of a goroutine's stack trace (its "goroutine N [state]:"
+// header plus the innermost function, the one the goroutine is currently in), used as the key of
+// each leak reported to the caller.
+func rootFrame(stack string) string {
+	lines := strings.SplitN(stack, "\n", 3)
+	if len(lines) < 2 {
+		return stack
+	}
+	return strings.TrimSpace(lines[0]) + " " + strings.TrimSpace(lines[1])
+}
@@ -0,0 +1,76 @@
+package dockerutil
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// fakeLeakT is a minimal leakCheckTestingT that records its registered cleanup func (so the test
+// can invoke it directly, rather than waiting for a real *testing.T to run it) and whatever
+// message, if any, was passed to Errorf.
+type fakeLeakT struct {
+	cleanup func()
+	errorf  string
+}
+
+func (f *fakeLeakT) Helper() {}
+
+func (f *fakeLeakT) Cleanup(fn func()) { f.cleanup = fn }
+
+func (f *fakeLeakT) Errorf(format string, args ...any) {
+	f.errorf = fmt.Sprintf(format, args...)
+}
+
+// TestNoLeakDetectsLeakedGoroutine starts a goroutine that outlives NoLeak's snapshot and never
+// matches any entry in ignoredLeakFrames, then asserts NoLeak's cleanup reports it via Errorf.
+func TestNoLeakDetectsLeakedGoroutine(t *testing.T) {
+	ft := &fakeLeakT{}
+	NoLeak(ft)
+
+	stop := make(chan struct{})
+	started := make(chan struct{})
+	go func() {
+		close(started)
+		<-stop
+	}()
+	<-started
+	defer close(stop)
+
+	ft.cleanup()
+
+	if ft.errorf == "" {
+		t.Fatalf("expected NoLeak to report the leaked goroutine, got no error")
+	}
+	if !strings.Contains(ft.errorf, "leaked goroutine") {
+		t.Fatalf("expected the error message to mention leaked goroutine(s), got: %s", ft.errorf)
+	}
+}
+
+// TestNoLeakIgnoresBenignPatterns starts and stops a goroutine whose stack matches an entry in
+// ignoredLeakFrames (readLoop, mimicking the docker client / http2 keepalive goroutines NoLeak is
+// meant to tolerate) and asserts NoLeak's cleanup does not report it.
+func TestNoLeakIgnoresBenignPatterns(t *testing.T) {
+	ft := &fakeLeakT{}
+	NoLeak(ft)
+
+	stop := make(chan struct{})
+	started := make(chan struct{})
+	go readLoopForTest(started, stop)
+	<-started
+
+	ft.cleanup()
+	close(stop)
+
+	if ft.errorf != "" {
+		t.Fatalf("expected no leak to be reported for a benign, ignored-pattern goroutine, got: %s", ft.errorf)
+	}
+}
+
+// readLoopForTest exists only so its name appears in its own goroutine's stack trace, matching
+// the "readLoop" entry in ignoredLeakFrames the same way the docker client's/http2's real
+// readLoop goroutines do.
+func readLoopForTest(started, stop chan struct{}) {
+	close(started)
+	<-stop
+}
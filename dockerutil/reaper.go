@@ -0,0 +1,141 @@
+package dockerutil
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/client"
+	"github.com/docker/go-connections/nat"
+)
+
+// ReaperImage is the default image used by startReaper. Its source lives alongside this file at
+// dockerutil/reaper (see dockerutil/reaper/Dockerfile for how to build and push it); set
+// ICTEST_REAPER_IMAGE to point at a locally built tag instead, e.g. for air-gapped environments.
+const ReaperImage = "ghcr.io/strangelove-ventures/interchaintest-reaper:latest"
+
+// reaperHeartbeatInterval is how often the test process pings the reaper to prove it's still alive.
+const reaperHeartbeatInterval = 5 * time.Second
+
+// reaperPort is the port the reaper listens on inside its container for the heartbeat connection.
+const reaperPort = "8080/tcp"
+
+// reaperPingMsg is sent on every heartbeat tick to prove the test process is still alive.
+const reaperPingMsg = "ping\n"
+
+// reaperShutdownMsg is sent once, right before the heartbeat connection is closed on a normal,
+// successful test completion. It tells the reaper this is an intentional disconnect, not a crash,
+// so it should stand down rather than reap the test's resources out from under DockerCleanup.
+const reaperShutdownMsg = "shutdown\n"
+
+// startReaper launches an opt-in "reaper" sidecar container that watches the docker socket and,
+// if it loses its heartbeat connection to this test process without first receiving
+// reaperShutdownMsg, removes every resource labeled CleanupLabel=t.Name() itself. This covers the
+// case that t.Cleanup can't: the Go test process being killed with SIGKILL (e.g. OOM-killed by
+// CI), which runs neither t.Cleanup nor the signal handlers installed by TrapCleanup. On a normal,
+// successful test completion the returned stop func sends reaperShutdownMsg before disconnecting,
+// so the reaper doesn't race DockerCleanup's own teardown on the happy path.
+//
+// startReaper is a no-op unless ICTEST_REAPER is set to a non-empty value, since it requires
+// pulling and running an extra container per test and most local runs don't need it.
+func startReaper(ctx context.Context, t DockerSetupTestingT, cli *client.Client, testName string) (stop func(), err error) {
+	if os.Getenv("ICTEST_REAPER") == "" {
+		return func() {}, nil
+	}
+
+	image := os.Getenv("ICTEST_REAPER_IMAGE")
+	if image == "" {
+		image = ReaperImage
+	}
+
+	if _, _, err := cli.ImageInspectWithRaw(ctx, image); err != nil {
+		rc, pullErr := cli.ImagePull(ctx, image, types.ImagePullOptions{})
+		if pullErr != nil {
+			return nil, fmt.Errorf("failed to pull reaper image %s: %w", image, pullErr)
+		}
+		_, _ = bufio.NewReader(rc).Discard(1 << 20)
+		_ = rc.Close()
+	}
+
+	created, err := cli.ContainerCreate(ctx,
+		&container.Config{
+			Image: image,
+			Env: []string{
+				"CLEANUP_LABEL=" + CleanupLabel + "=" + testName,
+			},
+			ExposedPorts: nat.PortSet{reaperPort: {}},
+			Labels: map[string]string{
+				NodeOwnerLabel: "reaper",
+			},
+		},
+		&container.HostConfig{
+			Mounts: []mount.Mount{
+				{
+					Type:   mount.TypeBind,
+					Source: "/var/run/docker.sock",
+					Target: "/var/run/docker.sock",
+				},
+			},
+			PortBindings: nat.PortMap{reaperPort: []nat.PortBinding{{HostIP: "127.0.0.1"}}},
+			AutoRemove:   true,
+		},
+		nil, nil, "reaper-"+RandLowerCaseLetterString(8),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create reaper container: %w", err)
+	}
+
+	if err := cli.ContainerStart(ctx, created.ID, types.ContainerStartOptions{}); err != nil {
+		return nil, fmt.Errorf("failed to start reaper container: %w", err)
+	}
+
+	inspect, err := cli.ContainerInspect(ctx, created.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect reaper container: %w", err)
+	}
+	bindings, ok := inspect.NetworkSettings.Ports[nat.Port(reaperPort)]
+	if !ok || len(bindings) == 0 {
+		return nil, fmt.Errorf("reaper container did not publish %s", reaperPort)
+	}
+	addr := net.JoinHostPort(bindings[0].HostIP, bindings[0].HostPort)
+
+	conn, err := net.DialTimeout("tcp", addr, 10*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to reaper at %s: %w", addr, err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(reaperHeartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if _, err := conn.Write([]byte(reaperPingMsg)); err != nil {
+					t.Logf("reaper: failed to send heartbeat: %v", err)
+					return
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+
+		// Tell the reaper this is a graceful shutdown before closing the connection, so it
+		// doesn't treat the disconnect as a lost heartbeat and reap the test's resources out from
+		// under DockerCleanup's own stop/log/remove sequence.
+		if _, err := conn.Write([]byte(reaperShutdownMsg)); err != nil {
+			t.Logf("reaper: failed to send shutdown message: %v", err)
+		}
+		_ = conn.Close()
+	}, nil
+}
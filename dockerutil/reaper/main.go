@@ -0,0 +1,122 @@
+// Command reaper is the sidecar image started by dockerutil.startReaper (ICTEST_REAPER=1). It
+// watches a single heartbeat connection from the interchaintest process that launched it and, if
+// that connection is lost without first receiving a graceful shutdown message, removes every
+// docker resource labeled with the cleanup label it was given. This exists to clean up after a
+// test process that's SIGKILLed (e.g. OOM-killed by CI), which runs neither the test's t.Cleanup
+// funcs nor the signal handlers installed by dockerutil.TrapCleanup.
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+)
+
+// heartbeatTimeout is how long the reaper waits for a heartbeat before assuming the test process
+// is gone. It must be comfortably larger than the interval dockerutil sends heartbeats on so a
+// single slow tick doesn't trigger a false reap.
+const heartbeatTimeout = 20 * time.Second
+
+const listenAddr = ":8080"
+
+// shutdownMsg must match dockerutil.reaperShutdownMsg.
+const shutdownMsg = "shutdown"
+
+func main() {
+	cleanupLabel := os.Getenv("CLEANUP_LABEL")
+	if cleanupLabel == "" {
+		log.Fatal("reaper: CLEANUP_LABEL must be set")
+	}
+
+	ln, err := net.Listen("tcp", listenAddr)
+	if err != nil {
+		log.Fatalf("reaper: failed to listen on %s: %v", listenAddr, err)
+	}
+	defer ln.Close()
+
+	conn, err := ln.Accept()
+	if err != nil {
+		log.Fatalf("reaper: failed to accept connection: %v", err)
+	}
+	defer conn.Close()
+
+	if waitForGracefulShutdown(conn) {
+		log.Print("reaper: received graceful shutdown, standing down without reaping")
+		return
+	}
+
+	log.Printf("reaper: lost heartbeat, reaping resources labeled %q", cleanupLabel)
+	if err := reap(cleanupLabel); err != nil {
+		log.Fatalf("reaper: failed to reap resources: %v", err)
+	}
+}
+
+// waitForGracefulShutdown reads lines from conn until it sees shutdownMsg (returns true), the
+// connection is closed, or no heartbeat arrives within heartbeatTimeout (both return false).
+func waitForGracefulShutdown(conn net.Conn) bool {
+	lines := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(conn)
+		for scanner.Scan() {
+			lines <- strings.TrimSpace(scanner.Text())
+		}
+		close(lines)
+	}()
+
+	for {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				return false
+			}
+			if line == shutdownMsg {
+				return true
+			}
+			// Anything else is a heartbeat ping; keep waiting.
+		case <-time.After(heartbeatTimeout):
+			return false
+		}
+	}
+}
+
+// reap removes every container, network, and volume labeled cleanupLabel (in "key=value" form).
+func reap(cleanupLabel string) error {
+	ctx := context.Background()
+
+	cli, err := client.NewClientWithOpts(client.FromEnv)
+	if err != nil {
+		return fmt.Errorf("failed to create docker client: %w", err)
+	}
+	defer cli.Close()
+	cli.NegotiateAPIVersion(ctx)
+
+	args := filters.NewArgs(filters.Arg("label", cleanupLabel))
+
+	containers, err := cli.ContainerList(ctx, types.ContainerListOptions{All: true, Filters: args})
+	if err != nil {
+		return fmt.Errorf("failed to list containers: %w", err)
+	}
+	for _, c := range containers {
+		if err := cli.ContainerRemove(ctx, c.ID, types.ContainerRemoveOptions{Force: true}); err != nil {
+			log.Printf("reaper: failed to remove container %s: %v", c.ID, err)
+		}
+	}
+
+	if _, err := cli.NetworksPrune(ctx, args); err != nil {
+		log.Printf("reaper: failed to prune networks: %v", err)
+	}
+	if _, err := cli.VolumesPrune(ctx, args); err != nil {
+		log.Printf("reaper: failed to prune volumes: %v", err)
+	}
+
+	return nil
+}
@@ -1,13 +1,10 @@
 package dockerutil
 
 import (
-	"archive/tar"
 	"bytes"
 	"context"
 	"fmt"
-	"io"
 	"os"
-	"path/filepath"
 	"strings"
 	"time"
 
@@ -71,13 +68,49 @@ func DockerSetup(t DockerSetupTestingT) (*client.Client, string) {
 		panic(fmt.Errorf("failed to create docker client: %v", err))
 	}
 
+	// t.Cleanup runs registered funcs in LIFO order, so registering the client Close first means
+	// it runs last, after every other cleanup func below has had a chance to use cli. Every
+	// *client.Client opens a pool of connections to the docker daemon; close it once the test is
+	// done so those connections and the goroutines serving them don't outlive the test.
+	t.Cleanup(func() {
+		if err := cli.Close(); err != nil {
+			t.Logf("Failed to close docker client: %v", err)
+		}
+	})
+
+	cleanup := DockerCleanup(t, cli, DockerExportCoverageDataFn(t, cli))
+
 	// Clean up docker resources at end of test, if enabled also collects coverage data.
-	t.Cleanup(DockerCleanup(t, cli, DockerExportCoverageDataFn(t, cli)))
+	t.Cleanup(cleanup)
+
+	// If the test process is killed by a signal (Ctrl-C locally, CI job cancellation) rather than
+	// reaching normal completion, t.Cleanup above never runs. Fall back to cleaning up on signal
+	// receipt instead, so resources aren't orphaned until the next DockerSetup call happens by.
+	unregisterTrap := TrapCleanup(func() { cleanup() })
+	t.Cleanup(unregisterTrap)
 
 	// Also eagerly clean up any leftover resources from a previous test run,
 	// e.g. if the test was interrupted. No coverage data is exported in this case.
 	DockerCleanup(t, cli, nil)()
 
+	// Optionally start a reaper sidecar that removes this test's resources itself if it loses its
+	// heartbeat connection to this process, covering the case of a SIGKILL that runs neither
+	// t.Cleanup nor the signal handler registered above.
+	stopReaper, err := startReaper(context.TODO(), t, cli, t.Name())
+	if err != nil {
+		t.Logf("Failed to start reaper: %v", err)
+	} else {
+		t.Cleanup(stopReaper)
+	}
+
+	// Snapshot goroutines for leak detection only after the signal trap and reaper heartbeat are
+	// installed above: both spawn long-lived goroutines that are still running when the leak check
+	// runs and must not be mistaken for a leak caused by this test. The trap's signal watcher and
+	// os/signal's own delivery goroutine are process-wide and outlive this test; the reaper's
+	// heartbeat ticker is stopped by stopReaper, which, being registered earlier, runs after the
+	// leak check's cleanup.
+	CheckGoroutineLeaks(t)
+
 	name := fmt.Sprintf("%s-%s", ICTDockerPrefix, RandLowerCaseLetterString(8))
 	network, err := cli.NetworkCreate(context.TODO(), name, types.NetworkCreate{
 		CheckDuplicate: true,
@@ -91,112 +124,6 @@ func DockerSetup(t DockerSetupTestingT) (*client.Client, string) {
 	return cli, network.ID
 }
 
-// DockerExportCoverageData guarantees the cleanup, but also exports coverage data from the containers beforehand.
-func DockerExportCoverageDataFn(t DockerSetupTestingT, cli *client.Client) func() {
-	return func() {
-		defer func() {
-			if e := recover(); e != nil {
-				t.Logf("Failed to export coverage data: %v", e)
-				return
-			}
-		}()
-
-		outCoverageDataDir := os.Getenv("ICTEST_GOCOVERDIR")
-		if outCoverageDataDir == "" {
-			outCoverageDataDir = "coverage/" + t.Name()
-		}
-
-		ctx := context.TODO()
-		cli.NegotiateAPIVersion(ctx)
-		cs, err := cli.ContainerList(ctx, types.ContainerListOptions{
-			All: true,
-			Filters: filters.NewArgs(
-				filters.Arg("label", CleanupLabel+"="+t.Name()),
-			),
-		})
-		if err != nil {
-			t.Logf("Failed to list containers during docker export coverage data: %v", err)
-			return
-		}
-
-		for _, c := range cs {
-			var coverageDataDir string
-
-			// Get GOCOVERDIR environment variable from container
-			containerInspect, err := cli.ContainerInspect(ctx, c.ID)
-			if err != nil {
-				t.Logf("Failed to inspect container %s: %v", c.ID, err)
-				continue
-			}
-
-			for _, env := range containerInspect.Config.Env {
-				if strings.HasPrefix(env, "GOCOVERDIR=") {
-					coverageDataDir = strings.TrimPrefix(env, "GOCOVERDIR=")
-					break
-				}
-			}
-
-			// coverage data not enabled for export
-			if coverageDataDir == "" {
-				continue
-			}
-
-			containerName := c.ID[:12]
-			if len(c.Names) > 0 {
-				containerName = c.Names[0]
-			}
-
-			t.Logf("Exporting coverage data %s from container %s", coverageDataDir, containerName)
-
-			// Copy coverage data from container to local filesystem
-			reader, _, err := cli.CopyFromContainer(ctx, c.ID, coverageDataDir)
-			if err != nil {
-				t.Logf("Failed to copy coverage data from container %s: %v", c.ID, err)
-				continue
-			}
-			defer reader.Close()
-
-			// Create full path for coverage data
-			containerCoverageDataDir := filepath.Join(outCoverageDataDir, containerName)
-			if err := os.MkdirAll(containerCoverageDataDir, 0755); err != nil {
-				t.Logf("Failed to create coverage data directory for container %s: %v", c.ID, err)
-				continue
-			}
-
-			// Extract the tar archive containing coverage data
-			tr := tar.NewReader(reader)
-			for {
-				header, err := tr.Next()
-				if err == io.EOF {
-					break
-				}
-				if err != nil {
-					t.Logf("Failed to read tar header from container %s: %v", c.ID, err)
-					break
-				}
-
-				// Skip directories
-				if header.Typeflag == tar.TypeDir {
-					continue
-				}
-
-				// Create coverage data file
-				outPath := filepath.Join(containerCoverageDataDir, filepath.Base(header.Name))
-				outFile, err := os.Create(outPath)
-				if err != nil {
-					t.Logf("Failed to create coverage data file %s: %v", outPath, err)
-					continue
-				}
-				defer outFile.Close()
-
-				if _, err := io.Copy(outFile, tr); err != nil {
-					t.Logf("Failed to write coverage data file %s: %v", outPath, err)
-				}
-			}
-		}
-	}
-}
-
 // DockerCleanup will clean up Docker containers, networks, and the other various config files generated in testing.
 func DockerCleanup(t DockerSetupTestingT, cli *client.Client, preRemoveCallback func()) func() {
 	return func() {
@@ -231,6 +158,7 @@ func DockerCleanup(t DockerSetupTestingT, cli *client.Client, preRemoveCallback
 				if err == nil {
 					b := new(bytes.Buffer)
 					_, err := b.ReadFrom(rc)
+					rc.Close()
 					if err == nil {
 						t.Logf("\n\nContainer logs - {%s}\n%s", strings.Join(c.Names, " "), b.String())
 					}
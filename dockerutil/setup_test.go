@@ -0,0 +1,88 @@
+package dockerutil
+
+import (
+	"context"
+	"os"
+	"runtime"
+	"testing"
+)
+
+// fakeSetupT is a minimal DockerSetupTestingT backed directly by *testing.T, used so
+// TestDockerSetupCleanupDoesNotLeak can drive DockerSetup/DockerCleanup in a loop without
+// registering hundreds of real t.Cleanup funcs against the outer test. Cleanup is overridden
+// rather than inherited from the embedded *testing.T: the embedded version would forward every
+// func DockerSetup registers (cli.Close, the trap unregister, the reaper stop, DockerCleanup
+// itself) to the *outer* test's cleanup queue, so none of them would actually run until the whole
+// test function returned - defeating the point of running DockerSetup/DockerCleanup per
+// iteration.
+type fakeSetupT struct {
+	*testing.T
+	name     string
+	cleanups []func()
+}
+
+func (f *fakeSetupT) Name() string { return f.name }
+
+func (f *fakeSetupT) Cleanup(fn func()) {
+	f.cleanups = append(f.cleanups, fn)
+}
+
+// runCleanups runs this iteration's registered cleanup funcs in LIFO order, matching testing.T's
+// own Cleanup ordering, so DockerSetup's cli.Close/trap-unregister/reaper-stop/DockerCleanup
+// sequence runs exactly as it would at real test-end.
+func (f *fakeSetupT) runCleanups() {
+	for i := len(f.cleanups) - 1; i >= 0; i-- {
+		f.cleanups[i]()
+	}
+	f.cleanups = nil
+}
+
+// TestDockerSetupCleanupDoesNotLeak runs DockerSetup followed immediately by its own cleanup in a
+// tight loop and asserts that neither open file descriptors nor goroutines grow unbounded. This
+// guards against regressions like an unclosed *client.Client or a ContainerLogs/CopyFromContainer
+// reader left open on an early-return path, which otherwise only shows up as resource exhaustion
+// after hundreds of tests in a long suite.
+func TestDockerSetupCleanupDoesNotLeak(t *testing.T) {
+	if os.Getenv("ICTEST_SKIP_DOCKER_TESTS") != "" {
+		t.Skip("skipping docker-dependent test")
+	}
+
+	const iterations = 20
+
+	startGoroutines := runtime.NumGoroutine()
+	startFDs := openFDCount(t)
+
+	for i := 0; i < iterations; i++ {
+		ft := &fakeSetupT{T: t, name: t.Name()}
+		cli, networkID := DockerSetup(ft)
+
+		if err := cli.NetworkRemove(context.TODO(), networkID); err != nil {
+			t.Logf("failed to remove network %s: %v", networkID, err)
+		}
+
+		// Runs exactly what DockerSetup registered via ft.Cleanup: cli.Close, the trap unregister,
+		// the reaper stop, and DockerCleanup itself - not a manually-duplicated approximation.
+		ft.runCleanups()
+	}
+
+	endGoroutines := runtime.NumGoroutine()
+	if endGoroutines > startGoroutines+5 {
+		t.Errorf("goroutine count grew from %d to %d over %d DockerSetup/DockerCleanup iterations", startGoroutines, endGoroutines, iterations)
+	}
+
+	if endFDs := openFDCount(t); startFDs > 0 && endFDs > startFDs+5 {
+		t.Errorf("open file descriptor count grew from %d to %d over %d DockerSetup/DockerCleanup iterations", startFDs, endFDs, iterations)
+	}
+}
+
+// openFDCount returns the number of open file descriptors for this process, or 0 if it can't be
+// determined (e.g. not running on Linux). Callers should treat 0 as "skip the assertion" rather
+// than a real count.
+func openFDCount(t *testing.T) int {
+	entries, err := os.ReadDir("/proc/self/fd")
+	if err != nil {
+		t.Logf("cannot count open file descriptors on this platform: %v", err)
+		return 0
+	}
+	return len(entries)
+}
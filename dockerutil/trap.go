@@ -0,0 +1,95 @@
+package dockerutil
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// trapSignalLimit is the number of interrupt signals DockerSetup will honor before giving up on
+// cleanup entirely and exiting immediately. This mirrors the escalation behavior of the docker
+// daemon's own signal trap, where repeated Ctrl-C presses tell the operator that the first
+// "graceful" attempt isn't working and they want out now.
+const trapSignalLimit = 3
+
+var (
+	trapOnce  sync.Once
+	trapMu    sync.Mutex
+	trapFuncs []func()
+)
+
+// TrapCleanup registers cleanup to run if the process receives SIGINT, SIGTERM, or SIGQUIT, and
+// returns a function that unregisters it. DockerSetup calls this so that a cancelled test run
+// (Ctrl-C locally, or a CI job cancellation) still tears down the containers/volumes/networks
+// tagged with CleanupLabel instead of leaving them orphaned until the next DockerSetup happens to
+// prune them.
+//
+// The underlying signal.Notify is installed only once per process; every call to TrapCleanup just
+// registers another cleanup func, so it's safe to call from many parallel tests sharing one
+// process. Registered funcs run in LIFO order, most-recently-registered first, matching the usual
+// defer ordering. If a third signal arrives before cleanup finishes, remaining cleanup is skipped
+// and the process exits immediately.
+func TrapCleanup(cleanup func()) (unregister func()) {
+	trapMu.Lock()
+	trapFuncs = append(trapFuncs, cleanup)
+	idx := len(trapFuncs) - 1
+	trapMu.Unlock()
+
+	trapOnce.Do(installTrap)
+
+	return func() {
+		trapMu.Lock()
+		defer trapMu.Unlock()
+		trapFuncs[idx] = nil
+	}
+}
+
+func installTrap() {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
+
+	go func() {
+		received := 0
+		for sig := range sigCh {
+			received++
+			if received >= trapSignalLimit {
+				fmt.Fprintf(os.Stderr, "dockerutil: received %s (%dx), skipping cleanup and exiting\n", sig, received)
+				os.Exit(1)
+			}
+
+			fmt.Fprintf(os.Stderr, "dockerutil: received %s, running docker cleanup before exit (press Ctrl-C %d more time(s) to skip)\n", sig, trapSignalLimit-received)
+
+			// Run cleanup and exit on a separate goroutine so that, if cleanup is slow or hangs,
+			// this loop keeps reading sigCh, counts a second signal, and exits on the third.
+			if received == 1 {
+				go func() {
+					runTrapFuncs()
+					os.Exit(1)
+				}()
+			}
+		}
+	}()
+}
+
+func runTrapFuncs() {
+	trapMu.Lock()
+	funcs := make([]func(), len(trapFuncs))
+	copy(funcs, trapFuncs)
+	trapMu.Unlock()
+
+	for i := len(funcs) - 1; i >= 0; i-- {
+		if funcs[i] == nil {
+			continue
+		}
+		func() {
+			defer func() {
+				if e := recover(); e != nil {
+					fmt.Fprintf(os.Stderr, "dockerutil: cleanup panicked: %v\n", e)
+				}
+			}()
+			funcs[i]()
+		}()
+	}
+}
@@ -0,0 +1,68 @@
+package dockerutil
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// TestTrapCleanupRunsOnSignal spawns a child process that registers a cleanup via TrapCleanup and
+// blocks forever, sends it SIGTERM, and asserts the cleanup ran before the process exited. This
+// exercises the real signal-handling path end-to-end; a pure in-process test can't do that safely,
+// since sending a real signal to the running test binary would kill the whole test run.
+func TestTrapCleanupRunsOnSignal(t *testing.T) {
+	if os.Getenv("ICTEST_TRAP_CLEANUP_CHILD") != "" {
+		runTrapCleanupChild()
+		return
+	}
+
+	markerFile := filepath.Join(t.TempDir(), "cleanup-ran")
+
+	cmd := exec.Command(os.Args[0], "-test.run=TestTrapCleanupRunsOnSignal")
+	cmd.Env = append(os.Environ(),
+		"ICTEST_TRAP_CLEANUP_CHILD=1",
+		"ICTEST_TRAP_CLEANUP_MARKER="+markerFile,
+	)
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("failed to start child process: %v", err)
+	}
+
+	// Give the child a moment to install its signal handler before signaling it.
+	time.Sleep(200 * time.Millisecond)
+
+	if err := cmd.Process.Signal(syscall.SIGTERM); err != nil {
+		t.Fatalf("failed to signal child process: %v", err)
+	}
+
+	waitErr := make(chan error, 1)
+	go func() { waitErr <- cmd.Wait() }()
+
+	select {
+	case err := <-waitErr:
+		// The child's signal handler calls os.Exit(1), so a non-zero exit is expected here.
+		if err == nil {
+			t.Fatalf("expected child process to exit non-zero after signal, got nil error")
+		}
+	case <-time.After(5 * time.Second):
+		_ = cmd.Process.Kill()
+		t.Fatalf("child process did not exit within 5s of receiving SIGTERM")
+	}
+
+	if _, err := os.Stat(markerFile); err != nil {
+		t.Fatalf("expected cleanup marker file to exist after signal, got: %v", err)
+	}
+}
+
+// runTrapCleanupChild is the child-process entrypoint used by TestTrapCleanupRunsOnSignal. It
+// registers a TrapCleanup that writes a marker file, then blocks until the process is killed by
+// the signal handler installed behind TrapCleanup.
+func runTrapCleanupChild() {
+	marker := os.Getenv("ICTEST_TRAP_CLEANUP_MARKER")
+	TrapCleanup(func() {
+		_ = os.WriteFile(marker, []byte("ok"), 0o644)
+	})
+	select {}
+}